@@ -0,0 +1,251 @@
+package ebur128
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultReportInterval is the interval between [Report]s when none is
+// specified, matching ffmpeg's ebur128 filter default.
+const DefaultReportInterval = 100 * time.Millisecond
+
+// Report is a point-in-time measurement emitted by an [Analyzer].
+type Report struct {
+	Time time.Duration // stream position of the report interval boundary, as audio time
+
+	Momentary  float64 // momentary loudness (last 400ms), in LUFS
+	Shortterm  float64 // short-term loudness (last 3s), in LUFS
+	Integrated float64 // running integrated loudness, in LUFS
+	LRA        float64 // running loudness range, in LU
+
+	SamplePeak []float64 // per-channel highest sample peak since the last report
+	TruePeak   []float64 // per-channel highest true peak since the last report
+}
+
+// Peaks are gathered per AddFrames* call: all of a call's peaks go to the
+// first report that call triggers (or to the next report, if it triggers
+// none), so further reports triggered by the same call carry zero peaks.
+
+// Summary is the final record emitted by [Analyzer.Close], covering the
+// whole program.
+//
+// It does not split the loudness range into its low/high bounds: the
+// underlying ebur128_loudness_range only returns the aggregate LRA, not
+// the 10th/95th percentile bounds it was computed from.
+type Summary struct {
+	Integrated float64 // integrated loudness, in LUFS
+	LRA        float64 // loudness range, in LU
+	Threshold  float64 // relative gating threshold, in LUFS
+	TruePeak   float64 // highest true peak across channels, in dBTP-linear units
+}
+
+// Analyzer wraps a [State] and, in addition to the existing terminal
+// getters, emits a time series of [Report]s every interval of *audio*
+// fed to it (not wall-clock time), mirroring the reporting model of
+// ffmpeg's ebur128 filter: a file decoded faster or slower than real time
+// still yields one report per interval of stream content, and Report.Time
+// reflects stream position rather than how long the analyzer has been
+// alive. Use it to build VU-meter UIs, broadcast-compliance loggers, or
+// JSON/CSV reporters.
+//
+// st must have been [Init]ed with at least ModeM|ModeS|ModeI|ModeLRA|
+// ModeSamplePeak|ModeTruePeak; otherwise the AddFrames* methods and Close
+// return the [ErrInvalidMode] of the getter whose mode is missing.
+type Analyzer struct {
+	st         *State
+	channels   int
+	sampleRate uint64
+
+	mu sync.Mutex
+
+	framesPerReport float64 // interval expressed in frames; may be fractional
+	frameAcc        float64 // frames fed since the last report emission
+	reportFrames    float64 // frame position of the next report boundary
+
+	samplePeak []float64 // per-channel highest sample peak since the last report
+	truePeak   []float64 // per-channel highest true peak since the last report
+
+	cb      func(Report)
+	reports chan Report
+}
+
+// NewAnalyzer creates an [Analyzer] wrapping st and emits a [Report] on the
+// channel returned by [Analyzer.Reports] every interval of audio fed to it
+// via the AddFrames* methods. If cb is non-nil, it is also called with
+// every report. If interval is not positive, [DefaultReportInterval] is used.
+//
+// channels and sampleRate must match how st was initialized.
+func NewAnalyzer(st *State, channels int, sampleRate uint64, interval time.Duration, cb func(Report)) *Analyzer {
+	if interval <= 0 {
+		interval = DefaultReportInterval
+	}
+	return &Analyzer{
+		st:              st,
+		channels:        channels,
+		sampleRate:      sampleRate,
+		framesPerReport: interval.Seconds() * float64(sampleRate),
+		samplePeak:      make([]float64, channels),
+		truePeak:        make([]float64, channels),
+		cb:              cb,
+		reports:         make(chan Report, 1),
+	}
+}
+
+// afterFrames folds the peaks of the block just fed to st into the
+// since-last-report running max and emits a [Report] for every report
+// interval the block crossed, each stamped with the frame position at
+// which that interval boundary fell rather than the position at the end
+// of the whole block. Must be called with a.mu held.
+func (a *Analyzer) afterFrames(frames int) error {
+	for c := 0; c < a.channels; c++ {
+		sp, err := a.st.PrevSamplePeak(uint(c))
+		if err != nil {
+			return err
+		}
+		if sp > a.samplePeak[c] {
+			a.samplePeak[c] = sp
+		}
+		tp, err := a.st.PrevTruePeak(uint(c))
+		if err != nil {
+			return err
+		}
+		if tp > a.truePeak[c] {
+			a.truePeak[c] = tp
+		}
+	}
+
+	a.frameAcc += float64(frames)
+	for a.framesPerReport > 0 && a.frameAcc >= a.framesPerReport {
+		a.frameAcc -= a.framesPerReport
+		a.reportFrames += a.framesPerReport
+
+		r, err := a.report(a.reportFrames)
+		if err != nil {
+			return err
+		}
+		if a.cb != nil {
+			a.cb(r)
+		}
+		select {
+		case a.reports <- r:
+		default: // slow consumer: drop rather than block the analyzer
+		}
+	}
+	return nil
+}
+
+// report builds a [Report] at stream position atFrames and resets the
+// since-last-report peak trackers. Must be called with a.mu held.
+func (a *Analyzer) report(atFrames float64) (Report, error) {
+	m, err := a.st.LoudnessMomentary()
+	if err != nil {
+		return Report{}, err
+	}
+	s, err := a.st.LoudnessShortterm()
+	if err != nil {
+		return Report{}, err
+	}
+	i, err := a.st.LoudnessGlobal()
+	if err != nil {
+		return Report{}, err
+	}
+	lra, err := a.st.LoudnessRange()
+	if err != nil {
+		return Report{}, err
+	}
+
+	r := Report{
+		Time:       time.Duration(atFrames / float64(a.sampleRate) * float64(time.Second)),
+		Momentary:  m,
+		Shortterm:  s,
+		Integrated: i,
+		LRA:        lra,
+		SamplePeak: append([]float64(nil), a.samplePeak...),
+		TruePeak:   append([]float64(nil), a.truePeak...),
+	}
+	for c := range a.samplePeak {
+		a.samplePeak[c] = 0
+		a.truePeak[c] = 0
+	}
+	return r, nil
+}
+
+// Reports returns the channel on which [Report]s are delivered. The
+// channel is buffered; a slow consumer will miss reports rather than block
+// the analyzer.
+func (a *Analyzer) Reports() <-chan Report { return a.reports }
+
+// AddFramesShort is [State.AddFramesShort], synchronized against concurrent
+// report generation, triggering a [Report] whenever frames crosses a
+// report interval boundary.
+func (a *Analyzer) AddFramesShort(src []int16, frames int) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err := a.st.AddFramesShort(src, frames); err != nil {
+		return err
+	}
+	return a.afterFrames(frames)
+}
+
+// AddFramesInt is [Analyzer.AddFramesShort] for int frames.
+func (a *Analyzer) AddFramesInt(src []int32, frames int) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err := a.st.AddFramesInt(src, frames); err != nil {
+		return err
+	}
+	return a.afterFrames(frames)
+}
+
+// AddFramesFloat is [Analyzer.AddFramesShort] for float frames.
+func (a *Analyzer) AddFramesFloat(src []float32, frames int) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err := a.st.AddFramesFloat(src, frames); err != nil {
+		return err
+	}
+	return a.afterFrames(frames)
+}
+
+// AddFramesDouble is [Analyzer.AddFramesShort] for double frames.
+func (a *Analyzer) AddFramesDouble(src []float64, frames int) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err := a.st.AddFramesDouble(src, frames); err != nil {
+		return err
+	}
+	return a.afterFrames(frames)
+}
+
+// Close returns a final [Summary] covering the whole program measured so
+// far. The [Analyzer] must not be used afterwards.
+func (a *Analyzer) Close() (Summary, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	i, err := a.st.LoudnessGlobal()
+	if err != nil {
+		return Summary{}, err
+	}
+	lra, err := a.st.LoudnessRange()
+	if err != nil {
+		return Summary{}, err
+	}
+	thresh, err := a.st.RelativeThreshold()
+	if err != nil {
+		return Summary{}, err
+	}
+
+	var tp float64
+	for c := 0; c < a.channels; c++ {
+		p, err := a.st.TruePeak(uint(c))
+		if err != nil {
+			return Summary{}, err
+		}
+		if p > tp {
+			tp = p
+		}
+	}
+
+	return Summary{Integrated: i, LRA: lra, Threshold: thresh, TruePeak: tp}, nil
+}
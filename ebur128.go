@@ -10,6 +10,7 @@ package ebur128
 */
 import "C"
 import (
+	"errors"
 	"time"
 	"unsafe"
 )
@@ -94,8 +95,27 @@ func newError(rc C.int) error {
 	return ebur128Error(rc)
 }
 
+// cstate is the direct, memory-layout-compatible binding to libebur128's
+// public struct.
+type cstate C.ebur128_state
+
 // State contains information about the state of a loudness measurement.
-type State C.ebur128_state
+//
+// Besides the library state itself, it owns the Go-side bookkeeping that
+// libebur128 has no getters for: the reusable interleaving scratch buffer
+// used by AddFramesPlanar* (see planar.go), and the max window/history
+// durations last set via [State.SetMaxWindow]/[State.SetMaxHistory] (see
+// summary.go). Keeping these on the [State] value itself, rather than in
+// a side table keyed by pointer, means they are freed by the garbage
+// collector along with the [State] instead of leaking when a caller
+// forgets to call [State.Destroy].
+type State struct {
+	raw *cstate
+
+	scratch    stateScratch
+	maxWindow  time.Duration
+	maxHistory time.Duration
+}
 
 // Modes
 //
@@ -119,24 +139,25 @@ func GetVersion() (major, minor, patch int) { //nolint: nonamedreturns // names
 }
 
 // c is a helper method to return the underlying [C.ebur128_state].
-func (st *State) c() *C.ebur128_state { return (*C.ebur128_state)(st) }
+func (st *State) c() *C.ebur128_state { return (*C.ebur128_state)(st.raw) }
 
 // Init initializes library [State].
 //   - channels the number of channels.
 //   - samplerate the sample rate.
 //   - mode see the mode enum for possible values.
 func Init(channels uint, sampleRate uint64, mode int) (*State, error) {
-	st := C.ebur128_init(C.uint(channels), C.ulong(sampleRate), C.int(mode))
-	if st == nil {
+	raw := C.ebur128_init(C.uint(channels), C.ulong(sampleRate), C.int(mode))
+	if raw == nil {
 		return nil, ErrNomem
 	}
-	return (*State)(st), nil
+	return &State{raw: (*cstate)(raw)}, nil
 }
 
 // Destroy destroys library [State].
 func (st *State) Destroy() {
 	cst := st.c()
 	C.ebur128_destroy(&cst) //nolint: gocritic // false positive, see: https://github.com/go-critic/go-critic/issues/897
+	st.raw = nil
 }
 
 // SetChannel sets channel type.
@@ -186,7 +207,11 @@ func (st *State) SetMaxWindow(window time.Duration) error {
 	}
 
 	rc := C.ebur128_set_max_window(st.c(), C.ulong(window.Milliseconds()))
-	return newError(rc)
+	err := newError(rc)
+	if !errors.Is(err, ErrNomem) {
+		st.maxWindow = window
+	}
+	return err
 }
 
 // SetMaxHistory sets the maximum history duration (ms precision) that will be stored for loudness integration.
@@ -205,7 +230,11 @@ func (st *State) SetMaxHistory(history time.Duration) error {
 	}
 
 	rc := C.ebur128_set_max_history(st.c(), C.ulong(history.Milliseconds()))
-	return newError(rc)
+	err := newError(rc)
+	if !errors.Is(err, ErrNomem) {
+		st.maxHistory = history
+	}
+	return err
 }
 
 // AddFramesShort adds frames to be processed.
@@ -370,8 +399,11 @@ type States []*State
 
 // c is a helper method to return the [States] as a "slice" of [C.ebur128_state].
 func (sts States) c() (**C.ebur128_state, C.size_t) {
-	ptr, size := unsafe.SliceData(sts), len(sts)
-	return (**C.ebur128_state)(unsafe.Pointer(ptr)), C.size_t(size)
+	ptrs := make([]*C.ebur128_state, len(sts))
+	for i, st := range sts {
+		ptrs[i] = st.c()
+	}
+	return (**C.ebur128_state)(unsafe.Pointer(unsafe.SliceData(ptrs))), C.size_t(len(ptrs))
 }
 
 // LoudnessGlobal returns global integrated loudness in LUFS or -HUGE_VAL if result is negative infinity across multiple instances.
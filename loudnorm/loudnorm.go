@@ -0,0 +1,99 @@
+// Package loudnorm implements EBU R128 loudness normalization on top of
+// [ebur128.State], modeled on ffmpeg's af_loudnorm filter.
+//
+// A two-pass normalization measures the whole program with an [Analyzer]
+// first, then applies gain with a [Normalizer] fed the same frames again in
+// a second pass. For live streams where a first pass is not possible, use
+// [NewLiveNormalizer] instead, which only performs dynamic limiting against
+// the target.
+package loudnorm
+
+import (
+	"github.com/oaiiae/ebur128-go"
+)
+
+// Default target values, matching ffmpeg's af_loudnorm defaults.
+const (
+	DefaultTargetI   = -23.0 // target integrated loudness, in LUFS
+	DefaultTargetLRA = 7.0   // target loudness range, in LU
+	DefaultTargetTP  = -2.0  // target true peak ceiling, in dBTP
+)
+
+// Config holds the normalization targets for an [Analyzer] and [Normalizer].
+type Config struct {
+	TargetI   float64 // target integrated loudness, in LUFS
+	TargetLRA float64 // target loudness range, in LU
+	TargetTP  float64 // target true peak ceiling, in dBTP
+}
+
+// DefaultConfig returns the ffmpeg af_loudnorm default targets.
+func DefaultConfig() Config {
+	return Config{TargetI: DefaultTargetI, TargetLRA: DefaultTargetLRA, TargetTP: DefaultTargetTP}
+}
+
+// Measurement is the pass 1 report produced by [Analyzer.Measurement], and
+// the post-normalization report produced by [Normalizer.Measurement].
+type Measurement struct {
+	InputI      float64 // integrated loudness, in LUFS
+	InputLRA    float64 // loudness range, in LU
+	InputTP     float64 // highest true peak across channels, in dBTP
+	InputThresh float64 // relative gating threshold, in LUFS
+}
+
+// Analyzer performs pass 1 of two-pass EBU R128 normalization: it measures
+// integrated loudness, loudness range and true peak over the whole program
+// so that a [Normalizer] can compute the gain to apply in pass 2.
+type Analyzer struct {
+	st       *ebur128.State
+	channels uint
+}
+
+// NewAnalyzer creates an [Analyzer] for the given channels and sample rate.
+func NewAnalyzer(channels uint, sampleRate uint64) (*Analyzer, error) {
+	st, err := ebur128.Init(channels, sampleRate, ebur128.ModeI|ebur128.ModeLRA|ebur128.ModeTruePeak|ebur128.ModeSamplePeak)
+	if err != nil {
+		return nil, err
+	}
+	return &Analyzer{st: st, channels: channels}, nil
+}
+
+// AddFrames feeds planar float32 frames to the analyzer.
+func (a *Analyzer) AddFrames(src [][]float32, frames int) error {
+	return a.st.AddFramesPlanarFloat(src, frames)
+}
+
+// Close releases the underlying [ebur128.State]. The [Analyzer] must not be
+// used afterwards.
+func (a *Analyzer) Close() {
+	a.st.Destroy()
+}
+
+// Measurement returns the pass 1 report. Call after all frames of the
+// program have been fed via AddFrames.
+func (a *Analyzer) Measurement() (Measurement, error) {
+	i, err := a.st.LoudnessGlobal()
+	if err != nil {
+		return Measurement{}, err
+	}
+	lra, err := a.st.LoudnessRange()
+	if err != nil {
+		return Measurement{}, err
+	}
+	thresh, err := a.st.RelativeThreshold()
+	if err != nil {
+		return Measurement{}, err
+	}
+
+	var tp float64
+	for ch := uint(0); ch < a.channels; ch++ {
+		peak, err := a.st.TruePeak(ch)
+		if err != nil {
+			return Measurement{}, err
+		}
+		if peak > tp {
+			tp = peak
+		}
+	}
+
+	return Measurement{InputI: i, InputLRA: lra, InputTP: linearToDB(tp), InputThresh: thresh}, nil
+}
@@ -0,0 +1,385 @@
+package loudnorm
+
+import (
+	"math"
+
+	"github.com/oaiiae/ebur128-go"
+)
+
+// Dynamic-mode tuning, chosen to track ffmpeg's af_loudnorm defaults: a 3s
+// look-ahead delay, gain reacting faster when it must come down (attack)
+// than when it can come back up (release).
+//
+// attackCoeff and releaseCoeff are the fraction of the remaining gain gap
+// closed per smoothingIntervalSeconds of audio; blockGain scales them to
+// the actual block duration so the smoothing is a function of time, not of
+// how the caller chunks Process calls.
+const (
+	lookaheadSeconds         = 3
+	smoothingIntervalSeconds = 0.1
+	attackCoeff              = 0.9
+	releaseCoeff             = 0.05
+)
+
+func linearToDB(x float64) float64 {
+	if x <= 0 {
+		return math.Inf(-1)
+	}
+	return 20 * math.Log10(x)
+}
+
+func dbToLinear(db float64) float64 {
+	return math.Pow(10, db/20)
+}
+
+func clamp32(v, ceiling float32) float32 {
+	switch {
+	case v > ceiling:
+		return ceiling
+	case v < -ceiling:
+		return -ceiling
+	default:
+		return v
+	}
+}
+
+func clamp64(v, ceiling float64) float64 {
+	switch {
+	case v > ceiling:
+		return ceiling
+	case v < -ceiling:
+		return -ceiling
+	default:
+		return v
+	}
+}
+
+// Normalizer applies pass 2 gain so the output matches the [Config]
+// targets, using either a single flat gain (linear mode) computed from a
+// [Measurement], or a per-block gain derived from momentary loudness with
+// attack/release smoothing and true-peak limiting (dynamic mode).
+//
+// Create one with [NewNormalizer] for two-pass normalization, or
+// [NewLiveNormalizer] for a single-pass live stream.
+type Normalizer struct {
+	cfg        Config
+	channels   uint
+	sampleRate uint64
+
+	linear bool
+	gainDB float64 // linear-mode flat gain
+
+	st         *ebur128.State // dynamic mode: momentary loudness and true peak of the current block
+	delayLen   int
+	pending    [][]float32
+	pendingF64 [][]float64
+	prevGainDB float64
+	tpCeiling  float64 // linear ceiling derived from cfg.TargetTP, hard-clamped against in dynamic mode
+
+	verify *ebur128.State // fed with the normalized output, for Measurement
+}
+
+// NewNormalizer creates a two-pass [Normalizer] from the [Measurement]
+// produced by an [Analyzer] over the same program.
+//
+// Linear mode is used when the measured loudness range fits within
+// cfg.TargetLRA and the resulting flat gain would not push the measured
+// true peak above cfg.TargetTP; otherwise dynamic mode is used.
+func NewNormalizer(channels uint, sampleRate uint64, cfg Config, m Measurement) (*Normalizer, error) {
+	n := &Normalizer{cfg: cfg, channels: channels, tpCeiling: dbToLinear(cfg.TargetTP)}
+
+	flatGainDB := cfg.TargetI - m.InputI
+	if m.InputLRA <= cfg.TargetLRA && m.InputTP+flatGainDB <= cfg.TargetTP {
+		n.linear = true
+		n.gainDB = flatGainDB
+	} else if err := n.initDynamic(channels, sampleRate); err != nil {
+		return nil, err
+	}
+
+	verify, err := ebur128.Init(channels, sampleRate, ebur128.ModeI|ebur128.ModeLRA|ebur128.ModeTruePeak|ebur128.ModeSamplePeak)
+	if err != nil {
+		return nil, err
+	}
+	n.verify = verify
+	return n, nil
+}
+
+// NewLiveNormalizer creates a single-pass [Normalizer] for live streams: it
+// skips the measurement pass and only performs dynamic limiting against the
+// target.
+func NewLiveNormalizer(channels uint, sampleRate uint64, cfg Config) (*Normalizer, error) {
+	n := &Normalizer{cfg: cfg, channels: channels, tpCeiling: dbToLinear(cfg.TargetTP)}
+	if err := n.initDynamic(channels, sampleRate); err != nil {
+		return nil, err
+	}
+
+	verify, err := ebur128.Init(channels, sampleRate, ebur128.ModeI|ebur128.ModeLRA|ebur128.ModeTruePeak|ebur128.ModeSamplePeak)
+	if err != nil {
+		n.Close()
+		return nil, err
+	}
+	n.verify = verify
+	return n, nil
+}
+
+func (n *Normalizer) initDynamic(channels uint, sampleRate uint64) error {
+	st, err := ebur128.Init(channels, sampleRate, ebur128.ModeM|ebur128.ModeTruePeak|ebur128.ModeSamplePeak)
+	if err != nil {
+		return err
+	}
+	n.st = st
+	n.sampleRate = sampleRate
+	n.delayLen = int(lookaheadSeconds * sampleRate)
+	n.pending = make([][]float32, channels)
+	n.pendingF64 = make([][]float64, channels)
+	return nil
+}
+
+// Close releases the underlying [ebur128.State] instances. The [Normalizer]
+// must not be used afterwards.
+func (n *Normalizer) Close() {
+	if n.st != nil {
+		n.st.Destroy()
+	}
+	if n.verify != nil {
+		n.verify.Destroy()
+	}
+}
+
+// blockGain computes the smoothed, true-peak-limited gain (in dB) to apply
+// to a block of frames frames long just fed to n.st, given the true linear
+// peak observed over that same block.
+//
+// The attack/release coefficients are tuned per smoothingIntervalSeconds of
+// audio; blockGain converts them to an equivalent coefficient for this
+// block's actual duration (frames/sampleRate) so the resulting time
+// constant is independent of how the caller chunks Process calls. The
+// target gain itself is still computed once per call, from the momentary
+// loudness after the block and that block's true peak, so the gain
+// trajectory still depends on the chunking.
+func (n *Normalizer) blockGain(truePeak float64, frames int) float64 {
+	momentary, err := n.st.LoudnessMomentary()
+	targetGainDB := n.prevGainDB
+	if err == nil && !math.IsInf(momentary, -1) {
+		targetGainDB = n.cfg.TargetI - momentary
+	}
+	if truePeak > 0 {
+		if ceil := n.cfg.TargetTP - linearToDB(truePeak); ceil < targetGainDB {
+			targetGainDB = ceil
+		}
+	}
+
+	base := releaseCoeff
+	if targetGainDB < n.prevGainDB {
+		base = attackCoeff
+	}
+	elapsed := float64(frames) / float64(n.sampleRate)
+	alpha := 1 - math.Pow(1-base, elapsed/smoothingIntervalSeconds)
+	n.prevGainDB += alpha * (targetGainDB - n.prevGainDB)
+	return n.prevGainDB
+}
+
+// blockTruePeak returns the highest per-channel true peak measured over the
+// block of frames just fed to n.st via AddFramesPlanar*.
+func (n *Normalizer) blockTruePeak() (float64, error) {
+	var peak float64
+	for c := uint(0); c < n.channels; c++ {
+		p, err := n.st.PrevTruePeak(c)
+		if err != nil {
+			return 0, err
+		}
+		if p > peak {
+			peak = p
+		}
+	}
+	return peak, nil
+}
+
+// ProcessFloat32 applies pass 2 gain to a block of planar float32 frames
+// and returns the processed planar frames. In dynamic mode, every output
+// sample is hard-clamped to cfg.TargetTP so a fast attack ramp cannot push
+// a sample past the ceiling.
+//
+// In dynamic mode, output is delayed by the look-ahead window: Process
+// buffers the first ~3s of frames and returns fewer frames than frames
+// while priming, then as many as were fed in steady state. Call
+// [Normalizer.FlushFloat32] after the last Process call to emit the
+// remaining buffered frames.
+func (n *Normalizer) ProcessFloat32(src [][]float32, frames int) ([][]float32, error) {
+	var out [][]float32
+	if n.linear {
+		gain := float32(dbToLinear(n.gainDB))
+		out = make([][]float32, n.channels)
+		for c := range out {
+			o := make([]float32, frames)
+			for i, v := range src[c][:frames] {
+				o[i] = v * gain
+			}
+			out[c] = o
+		}
+	} else {
+		if err := n.st.AddFramesPlanarFloat(src, frames); err != nil {
+			return nil, err
+		}
+		peak, err := n.blockTruePeak()
+		if err != nil {
+			return nil, err
+		}
+		gain := float32(dbToLinear(n.blockGain(peak, frames)))
+		ceiling := float32(n.tpCeiling)
+
+		for c := range n.pending {
+			n.pending[c] = append(n.pending[c], src[c][:frames]...)
+		}
+		if emit := len(n.pending[0]) - n.delayLen; emit > 0 {
+			out = make([][]float32, n.channels)
+			for c := range out {
+				chunk := n.pending[c][:emit]
+				o := make([]float32, emit)
+				for i, v := range chunk {
+					o[i] = clamp32(v*gain, ceiling)
+				}
+				out[c] = o
+				n.pending[c] = n.pending[c][emit:]
+			}
+		}
+	}
+
+	if len(out) > 0 {
+		if err := n.verify.AddFramesPlanarFloat(out, len(out[0])); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// FlushFloat32 drains and gain-applies the frames still buffered in the
+// look-ahead delay, returning the final planar output. Call it once after
+// the last ProcessFloat32 call. In linear mode there is nothing buffered
+// and it returns (nil, nil).
+func (n *Normalizer) FlushFloat32() ([][]float32, error) {
+	if n.linear || len(n.pending) == 0 || len(n.pending[0]) == 0 {
+		return nil, nil
+	}
+
+	gain := float32(dbToLinear(n.prevGainDB))
+	ceiling := float32(n.tpCeiling)
+	out := make([][]float32, n.channels)
+	for c := range out {
+		chunk := n.pending[c]
+		o := make([]float32, len(chunk))
+		for i, v := range chunk {
+			o[i] = clamp32(v*gain, ceiling)
+		}
+		out[c] = o
+		n.pending[c] = nil
+	}
+
+	if err := n.verify.AddFramesPlanarFloat(out, len(out[0])); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ProcessFloat64 is [Normalizer.ProcessFloat32] for double frames.
+func (n *Normalizer) ProcessFloat64(src [][]float64, frames int) ([][]float64, error) {
+	var out [][]float64
+	if n.linear {
+		gain := dbToLinear(n.gainDB)
+		out = make([][]float64, n.channels)
+		for c := range out {
+			o := make([]float64, frames)
+			for i, v := range src[c][:frames] {
+				o[i] = v * gain
+			}
+			out[c] = o
+		}
+	} else {
+		if err := n.st.AddFramesPlanarDouble(src, frames); err != nil {
+			return nil, err
+		}
+		peak, err := n.blockTruePeak()
+		if err != nil {
+			return nil, err
+		}
+		gain := dbToLinear(n.blockGain(peak, frames))
+
+		for c := range n.pendingF64 {
+			n.pendingF64[c] = append(n.pendingF64[c], src[c][:frames]...)
+		}
+		if emit := len(n.pendingF64[0]) - n.delayLen; emit > 0 {
+			out = make([][]float64, n.channels)
+			for c := range out {
+				chunk := n.pendingF64[c][:emit]
+				o := make([]float64, emit)
+				for i, v := range chunk {
+					o[i] = clamp64(v*gain, n.tpCeiling)
+				}
+				out[c] = o
+				n.pendingF64[c] = n.pendingF64[c][emit:]
+			}
+		}
+	}
+
+	if len(out) > 0 {
+		if err := n.verify.AddFramesPlanarDouble(out, len(out[0])); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// FlushFloat64 is [Normalizer.FlushFloat32] for double frames.
+func (n *Normalizer) FlushFloat64() ([][]float64, error) {
+	if n.linear || len(n.pendingF64) == 0 || len(n.pendingF64[0]) == 0 {
+		return nil, nil
+	}
+
+	gain := dbToLinear(n.prevGainDB)
+	out := make([][]float64, n.channels)
+	for c := range out {
+		chunk := n.pendingF64[c]
+		o := make([]float64, len(chunk))
+		for i, v := range chunk {
+			o[i] = clamp64(v*gain, n.tpCeiling)
+		}
+		out[c] = o
+		n.pendingF64[c] = nil
+	}
+
+	if err := n.verify.AddFramesPlanarDouble(out, len(out[0])); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Measurement returns a post-normalization report over the frames emitted
+// by Process (and Flush) so far, for verification against the [Config]
+// targets.
+func (n *Normalizer) Measurement() (Measurement, error) {
+	i, err := n.verify.LoudnessGlobal()
+	if err != nil {
+		return Measurement{}, err
+	}
+	lra, err := n.verify.LoudnessRange()
+	if err != nil {
+		return Measurement{}, err
+	}
+	thresh, err := n.verify.RelativeThreshold()
+	if err != nil {
+		return Measurement{}, err
+	}
+
+	var tp float64
+	for c := uint(0); c < n.channels; c++ {
+		peak, err := n.verify.TruePeak(c)
+		if err != nil {
+			return Measurement{}, err
+		}
+		if peak > tp {
+			tp = peak
+		}
+	}
+
+	return Measurement{InputI: i, InputLRA: lra, InputTP: linearToDB(tp), InputThresh: thresh}, nil
+}
@@ -0,0 +1,150 @@
+package ebur128
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+)
+
+// SampleFormat identifies a raw PCM sample encoding accepted by [Meter].
+type SampleFormat int
+
+const (
+	SampleFormatS16LE SampleFormat = iota // signed 16-bit little-endian
+	SampleFormatS16BE                     // signed 16-bit big-endian
+	SampleFormatS32LE                     // signed 32-bit little-endian
+	SampleFormatS32BE                     // signed 32-bit big-endian
+	SampleFormatF32LE                     // 32-bit float little-endian
+	SampleFormatF32BE                     // 32-bit float big-endian
+	SampleFormatF64LE                     // 64-bit float little-endian
+	SampleFormatF64BE                     // 64-bit float big-endian
+)
+
+// bytesPerSample returns the size, in bytes, of one sample in f.
+func (f SampleFormat) bytesPerSample() int {
+	switch f {
+	case SampleFormatS16LE, SampleFormatS16BE:
+		return 2
+	case SampleFormatS32LE, SampleFormatS32BE, SampleFormatF32LE, SampleFormatF32BE:
+		return 4
+	case SampleFormatF64LE, SampleFormatF64BE:
+		return 8
+	default:
+		panic("ebur128: unknown sample format")
+	}
+}
+
+// byteOrder returns the [binary.ByteOrder] of f.
+func (f SampleFormat) byteOrder() binary.ByteOrder {
+	switch f {
+	case SampleFormatS16BE, SampleFormatS32BE, SampleFormatF32BE, SampleFormatF64BE:
+		return binary.BigEndian
+	default:
+		return binary.LittleEndian
+	}
+}
+
+// Meter wraps a [State] as an [io.Writer] accepting raw interleaved PCM in
+// a given [SampleFormat], so callers can io.Copy raw audio straight from an
+// ffmpeg subprocess, HTTP body, or file into the meter without manually
+// decoding sample formats or worrying about short/partial writes.
+//
+// Write buffers any trailing partial frame across calls.
+type Meter struct {
+	st       *State
+	format   SampleFormat
+	channels int
+
+	partial []byte // bytes held over from a Write ending mid-frame
+}
+
+// NewMeter creates a [Meter] writing decoded frames into st.
+//   - format the raw PCM sample encoding that Write will be given.
+//   - channels the number of channels st was initialized with.
+func NewMeter(st *State, format SampleFormat, channels int) *Meter {
+	return &Meter{st: st, format: format, channels: channels}
+}
+
+// Write implements [io.Writer]. It decodes as many complete frames as p
+// contains and adds them to the underlying [State], buffering any trailing
+// partial frame for the next call.
+func (m *Meter) Write(p []byte) (int, error) {
+	n := len(p)
+	if len(m.partial) > 0 {
+		p = append(m.partial, p...)
+		m.partial = nil
+	}
+
+	frameSize := m.format.bytesPerSample() * m.channels
+	usable := len(p) - len(p)%frameSize
+	if usable > 0 {
+		if err := m.addFrames(p[:usable]); err != nil {
+			return n, err
+		}
+	}
+	if rest := p[usable:]; len(rest) > 0 {
+		m.partial = append(m.partial[:0], rest...)
+	}
+	return n, nil
+}
+
+// addFrames decodes b, which must hold a whole number of frames in
+// m.format, and adds them to m.st.
+func (m *Meter) addFrames(b []byte) error {
+	order := m.format.byteOrder()
+	frames := len(b) / (m.format.bytesPerSample() * m.channels)
+
+	switch m.format {
+	case SampleFormatS16LE, SampleFormatS16BE:
+		src := make([]int16, frames*m.channels)
+		for i := range src {
+			src[i] = int16(order.Uint16(b[i*2:]))
+		}
+		return m.st.AddFramesShort(src, frames)
+	case SampleFormatS32LE, SampleFormatS32BE:
+		src := make([]int32, frames*m.channels)
+		for i := range src {
+			src[i] = int32(order.Uint32(b[i*4:]))
+		}
+		return m.st.AddFramesInt(src, frames)
+	case SampleFormatF32LE, SampleFormatF32BE:
+		src := make([]float32, frames*m.channels)
+		for i := range src {
+			src[i] = math.Float32frombits(order.Uint32(b[i*4:]))
+		}
+		return m.st.AddFramesFloat(src, frames)
+	case SampleFormatF64LE, SampleFormatF64BE:
+		src := make([]float64, frames*m.channels)
+		for i := range src {
+			src[i] = math.Float64frombits(order.Uint64(b[i*8:]))
+		}
+		return m.st.AddFramesDouble(src, frames)
+	default:
+		panic("ebur128: unknown sample format")
+	}
+}
+
+// MeterReader wraps an [io.Reader], teeing every byte read through a
+// [Meter] before returning it to the caller, so transcoding pipelines can
+// measure loudness without a second pass over the data.
+type MeterReader struct {
+	r     io.Reader
+	meter *Meter
+}
+
+// NewMeterReader creates a [MeterReader] reading from r and feeding every
+// byte read into meter.
+func NewMeterReader(r io.Reader, meter *Meter) *MeterReader {
+	return &MeterReader{r: r, meter: meter}
+}
+
+// Read implements [io.Reader].
+func (mr *MeterReader) Read(p []byte) (int, error) {
+	n, err := mr.r.Read(p)
+	if n > 0 {
+		if _, werr := mr.meter.Write(p[:n]); werr != nil && err == nil {
+			err = werr
+		}
+	}
+	return n, err
+}
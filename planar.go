@@ -0,0 +1,120 @@
+package ebur128
+
+/*
+#include <ebur128.h>
+*/
+import "C"
+import (
+	"errors"
+)
+
+// Planar ingestion errors
+//
+// Returned by the AddFramesPlanar* methods when src does not describe a
+// valid planar layout for the state's configured channel count.
+var (
+	// ErrPlanarChannels is returned when len(src) does not match the number
+	// of channels configured on the [State].
+	ErrPlanarChannels error = errors.New("ebur128: len(src) does not match channel count")
+	// ErrPlanarFrames is returned when a channel slice in src holds fewer
+	// than frames samples.
+	ErrPlanarFrames error = errors.New("ebur128: planar channel shorter than frames")
+)
+
+// stateScratch holds the reusable interleaving buffers for a [State],
+// grown on demand and reused across AddFramesPlanar* calls.
+type stateScratch struct {
+	i16 []int16
+	i32 []int32
+	f32 []float32
+	f64 []float64
+}
+
+// checkPlanar validates that src holds exactly channels slices, each with
+// at least frames samples.
+func checkPlanar[T any](channels int, src [][]T, frames int) error {
+	if len(src) != channels {
+		return ErrPlanarChannels
+	}
+	for _, ch := range src {
+		if len(ch) < frames {
+			return ErrPlanarFrames
+		}
+	}
+	return nil
+}
+
+func grow[T any](buf []T, n int) []T {
+	if cap(buf) < n {
+		return make([]T, n)
+	}
+	return buf[:n]
+}
+
+// AddFramesPlanarShort is [State.AddFramesShort] for planar (non-interleaved)
+// source data.
+//
+// src must hold one slice per configured channel, each with at least frames
+// samples. The frames are interleaved into st's scratch buffer, grown on
+// demand and reused across calls, before being handed off to libebur128.
+//
+// Returns [ErrPlanarChannels] or [ErrPlanarFrames] if src is not shaped as
+// described above.
+func (st *State) AddFramesPlanarShort(src [][]int16, frames int) error {
+	if err := checkPlanar(int(st.c().channels), src, frames); err != nil {
+		return err
+	}
+
+	st.scratch.i16 = grow(st.scratch.i16, frames*len(src))
+	for f := 0; f < frames; f++ {
+		for c, ch := range src {
+			st.scratch.i16[f*len(src)+c] = ch[f]
+		}
+	}
+	return st.AddFramesShort(st.scratch.i16, frames)
+}
+
+// AddFramesPlanarInt is [State.AddFramesPlanarShort] for int frames.
+func (st *State) AddFramesPlanarInt(src [][]int32, frames int) error {
+	if err := checkPlanar(int(st.c().channels), src, frames); err != nil {
+		return err
+	}
+
+	st.scratch.i32 = grow(st.scratch.i32, frames*len(src))
+	for f := 0; f < frames; f++ {
+		for c, ch := range src {
+			st.scratch.i32[f*len(src)+c] = ch[f]
+		}
+	}
+	return st.AddFramesInt(st.scratch.i32, frames)
+}
+
+// AddFramesPlanarFloat is [State.AddFramesPlanarShort] for float frames.
+func (st *State) AddFramesPlanarFloat(src [][]float32, frames int) error {
+	if err := checkPlanar(int(st.c().channels), src, frames); err != nil {
+		return err
+	}
+
+	st.scratch.f32 = grow(st.scratch.f32, frames*len(src))
+	for f := 0; f < frames; f++ {
+		for c, ch := range src {
+			st.scratch.f32[f*len(src)+c] = ch[f]
+		}
+	}
+	return st.AddFramesFloat(st.scratch.f32, frames)
+}
+
+// AddFramesPlanarDouble is [State.AddFramesPlanarShort] for double frames.
+func (st *State) AddFramesPlanarDouble(src [][]float64, frames int) error {
+	if err := checkPlanar(int(st.c().channels), src, frames); err != nil {
+		return err
+	}
+
+	st.scratch.f64 = grow(st.scratch.f64, frames*len(src))
+	for f := 0; f < frames; f++ {
+		for c, ch := range src {
+			st.scratch.f64[f*len(src)+c] = ch[f]
+		}
+	}
+	return st.AddFramesDouble(st.scratch.f64, frames)
+}
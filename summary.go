@@ -0,0 +1,200 @@
+package ebur128
+
+/*
+#include <ebur128.h>
+*/
+import "C"
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+const (
+	summaryMagic   = "EBUR128S"
+	summaryVersion = 1
+)
+
+// EncodeSummary serializes st's configuration (channels, sample rate, mode,
+// max window/history) and its current scalar measurements into a versioned
+// binary format.
+//
+// This is NOT a checkpoint/resume mechanism: libebur128's histogram
+// buckets, which is where the integrated loudness and loudness range
+// measurements actually live, are private to the library and not reachable
+// through its public C API, so EncodeSummary cannot persist them, and
+// [InitFromSummary] cannot replay them into a new [State]. A state created
+// by [InitFromSummary] starts with an empty measurement history; it does
+// not continue accumulating where the original left off, and summing its
+// measurements with [States.LoudnessGlobal] or [States.LoudnessRange]
+// alongside the original would sum an empty state, not the original
+// program. True resume would require either keeping the original process
+// (and [State]) alive, or a patched libebur128 exposing its internal
+// histogram for export/import.
+//
+// What EncodeSummary and [InitFromSummary] do provide: a durable record of
+// the configuration needed to re-[Init] an equivalent [State], and of the
+// scalar measurements (integrated loudness, LRA, relative threshold, and
+// per-channel sample/true peak) current at the time EncodeSummary was
+// called, recoverable with [DecodeSummary] without needing a [State] at
+// all — useful for logging or auditing a prior measurement.
+func (st *State) EncodeSummary() ([]byte, error) {
+	c := st.c()
+	channels := int(c.channels)
+
+	i, err := st.LoudnessGlobal()
+	if err != nil {
+		return nil, err
+	}
+	lra, err := st.LoudnessRange()
+	if err != nil {
+		return nil, err
+	}
+	thresh, err := st.RelativeThreshold()
+	if err != nil {
+		return nil, err
+	}
+
+	samplePeaks := make([]float64, channels)
+	truePeaks := make([]float64, channels)
+	for ch := 0; ch < channels; ch++ {
+		if samplePeaks[ch], err = st.SamplePeak(uint(ch)); err != nil {
+			return nil, err
+		}
+		if truePeaks[ch], err = st.TruePeak(uint(ch)); err != nil {
+			return nil, err
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	buf.WriteString(summaryMagic)
+	for _, v := range []any{
+		uint32(summaryVersion),
+		uint32(c.channels), uint64(c.samplerate), uint32(c.mode),
+		int64(st.maxWindow), int64(st.maxHistory),
+		i, lra, thresh,
+	} {
+		if err := binary.Write(buf, binary.BigEndian, v); err != nil {
+			return nil, err
+		}
+	}
+	for _, p := range samplePeaks {
+		if err := binary.Write(buf, binary.BigEndian, p); err != nil {
+			return nil, err
+		}
+	}
+	for _, p := range truePeaks {
+		if err := binary.Write(buf, binary.BigEndian, p); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// encodedSummary is the fully decoded content of a payload produced by
+// [State.EncodeSummary].
+type encodedSummary struct {
+	channels, mode uint32
+	sampleRate     uint64
+	maxWindow      time.Duration
+	maxHistory     time.Duration
+
+	integrated, lra, thresh float64
+	samplePeaks, truePeaks  []float64
+}
+
+func decodeSummary(encoded []byte) (encodedSummary, error) {
+	var h encodedSummary
+	r := bytes.NewReader(encoded)
+
+	magic := make([]byte, len(summaryMagic))
+	if _, err := io.ReadFull(r, magic); err != nil || string(magic) != summaryMagic {
+		return h, errors.New("ebur128: invalid encoded summary")
+	}
+
+	var version uint32
+	if err := binary.Read(r, binary.BigEndian, &version); err != nil {
+		return h, err
+	}
+	if version != summaryVersion {
+		return h, fmt.Errorf("ebur128: unsupported encoded summary version %d", version)
+	}
+
+	var maxWindow, maxHistory int64
+	fields := []any{
+		&h.channels, &h.sampleRate, &h.mode, &maxWindow, &maxHistory,
+		&h.integrated, &h.lra, &h.thresh,
+	}
+	for _, f := range fields {
+		if err := binary.Read(r, binary.BigEndian, f); err != nil {
+			return h, err
+		}
+	}
+	h.maxWindow = time.Duration(maxWindow)
+	h.maxHistory = time.Duration(maxHistory)
+
+	h.samplePeaks = make([]float64, h.channels)
+	h.truePeaks = make([]float64, h.channels)
+	for ch := range h.samplePeaks {
+		if err := binary.Read(r, binary.BigEndian, &h.samplePeaks[ch]); err != nil {
+			return h, err
+		}
+	}
+	for ch := range h.truePeaks {
+		if err := binary.Read(r, binary.BigEndian, &h.truePeaks[ch]); err != nil {
+			return h, err
+		}
+	}
+	return h, nil
+}
+
+// DecodeSummary decodes the scalar measurements packaged by
+// [State.EncodeSummary], without reconstructing a [State]. TruePeak is the
+// highest per-channel true peak in the payload.
+func DecodeSummary(encoded []byte) (Summary, error) {
+	h, err := decodeSummary(encoded)
+	if err != nil {
+		return Summary{}, err
+	}
+
+	var tp float64
+	for _, p := range h.truePeaks {
+		if p > tp {
+			tp = p
+		}
+	}
+	return Summary{Integrated: h.integrated, LRA: h.lra, Threshold: h.thresh, TruePeak: tp}, nil
+}
+
+// InitFromSummary [Init]s a [State] with the configuration packaged by
+// [State.EncodeSummary]: channels, sample rate, mode, and max
+// window/history. It does NOT restore measurement history — see
+// [State.EncodeSummary] for why. Use [DecodeSummary] to recover the scalar
+// measurements that were current when the summary was encoded.
+func InitFromSummary(encoded []byte) (*State, error) {
+	h, err := decodeSummary(encoded)
+	if err != nil {
+		return nil, err
+	}
+
+	st, err := Init(uint(h.channels), h.sampleRate, int(h.mode))
+	if err != nil {
+		return nil, err
+	}
+	if h.maxWindow > 0 {
+		if err := st.SetMaxWindow(h.maxWindow); err != nil && !errors.Is(err, ErrNoChange) {
+			st.Destroy()
+			return nil, err
+		}
+	}
+	if h.maxHistory > 0 {
+		if err := st.SetMaxHistory(h.maxHistory); err != nil && !errors.Is(err, ErrNoChange) {
+			st.Destroy()
+			return nil, err
+		}
+	}
+	return st, nil
+}